@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"iter"
+)
+
+const (
+	chunkWindowSize = 64               // bytes considered by the rolling hash
+	chunkMinSize    = 512 * 1024       // hard floor, avoids pathologically small chunks
+	chunkMaxSize    = 8 * 1024 * 1024  // hard ceiling, bounds worst-case chunk size
+	chunkMaskBits   = 20               // low bits checked for a cut point (~1 MiB average)
+	rollingPrime    = uint64(1099511628211)
+)
+
+// Chunk is one content-defined slice of a file, identified by its SHA-256
+// digest so identical content across different files collapses to the same
+// row in the chunks table.
+type Chunk struct {
+	Offset int64
+	Length int64
+	SHA256 string
+}
+
+// Chunker splits a stream into variable-sized, content-defined chunks using
+// a rolling Rabin fingerprint over a sliding window, the same approach
+// restic uses for its deduplicating backup chunker. Cutting on content
+// rather than fixed offsets means inserting or deleting bytes in the middle
+// of a file only perturbs the chunks around the edit, so duplicate content
+// elsewhere in the file (or in other files) still hashes identically.
+type Chunker struct {
+	WindowSize int
+	MinSize    int
+	MaxSize    int
+	MaskBits   uint
+
+	err error
+}
+
+// Err returns the terminal error from the most recently completed Split
+// call, or nil if the input was read to EOF. A dropped connection or other
+// I/O error partway through a large file otherwise looks identical to the
+// file simply ending, so callers must check Err after ranging over Split's
+// Seq before trusting the chunks they got.
+func (c *Chunker) Err() error {
+	return c.err
+}
+
+// NewChunker returns a Chunker configured for ~1 MiB average chunk size,
+// matching the chunking.enabled feature's defaults.
+func NewChunker() *Chunker {
+	return &Chunker{
+		WindowSize: chunkWindowSize,
+		MinSize:    chunkMinSize,
+		MaxSize:    chunkMaxSize,
+		MaskBits:   chunkMaskBits,
+	}
+}
+
+// Split reads r to EOF and yields each content-defined chunk in order. If r
+// returns a non-EOF error, Split stops early and records it; check Err after
+// ranging over the returned Seq.
+func (c *Chunker) Split(r io.Reader) iter.Seq[Chunk] {
+	return func(yield func(Chunk) bool) {
+		c.err = nil
+		br := bufio.NewReaderSize(r, 64*1024)
+
+		var primePowWindow uint64 = 1
+		for i := 0; i < c.WindowSize; i++ {
+			primePowWindow *= rollingPrime
+		}
+		mask := uint64(1)<<c.MaskBits - 1
+
+		var (
+			buf    []byte
+			window = make([]byte, c.WindowSize)
+			widx   int
+			filled int
+			hash   uint64
+			offset int64
+		)
+
+		flush := func() bool {
+			sum := sha256.Sum256(buf)
+			chunk := Chunk{Offset: offset, Length: int64(len(buf)), SHA256: hex.EncodeToString(sum[:])}
+			offset += int64(len(buf))
+			buf = buf[:0]
+			hash, filled, widx = 0, 0, 0
+			for i := range window {
+				window[i] = 0
+			}
+			return yield(chunk)
+		}
+
+		for {
+			b, err := br.ReadByte()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				c.err = err
+				return
+			}
+
+			buf = append(buf, b)
+			old := window[widx]
+			hash = hash*rollingPrime + uint64(b) - uint64(old)*primePowWindow
+			window[widx] = b
+			widx = (widx + 1) % c.WindowSize
+			if filled < c.WindowSize {
+				filled++
+			}
+
+			n := len(buf)
+			atCutPoint := n >= c.MinSize && filled == c.WindowSize && hash&mask == 0
+			if n >= c.MaxSize || atCutPoint {
+				if !flush() {
+					return
+				}
+			}
+		}
+
+		if len(buf) > 0 {
+			flush()
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestChunkerRespectsMinAndMaxSize(t *testing.T) {
+	c := &Chunker{WindowSize: 8, MinSize: 16, MaxSize: 32, MaskBits: 1}
+	data := make([]byte, 300)
+	for i := range data {
+		data[i] = byte(i * 37)
+	}
+
+	var chunks []Chunk
+	for chunk := range c.Split(bytes.NewReader(data)) {
+		chunks = append(chunks, chunk)
+	}
+
+	for i, chunk := range chunks {
+		if chunk.Length > int64(c.MaxSize) {
+			t.Errorf("chunk %d length %d exceeds MaxSize %d", i, chunk.Length, c.MaxSize)
+		}
+		isLast := i == len(chunks)-1
+		if !isLast && chunk.Length < int64(c.MinSize) {
+			t.Errorf("chunk %d length %d is below MinSize %d", i, chunk.Length, c.MinSize)
+		}
+	}
+}
+
+func TestChunkerIsDeterministic(t *testing.T) {
+	data := bytes.Repeat([]byte("the quick brown fox jumps over the lazy dog"), 100000)
+
+	split := func() []Chunk {
+		c := NewChunker()
+		var chunks []Chunk
+		for chunk := range c.Split(bytes.NewReader(data)) {
+			chunks = append(chunks, chunk)
+		}
+		return chunks
+	}
+
+	first := split()
+	second := split()
+
+	if len(first) != len(second) {
+		t.Fatalf("chunk count differs between runs: %d vs %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("chunk %d differs between runs: %+v vs %+v", i, first[i], second[i])
+		}
+	}
+}
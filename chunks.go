@@ -0,0 +1,28 @@
+package main
+
+import "fmt"
+
+// chunkAndStoreFile re-reads a just-scanned file through scanner, splits it
+// with the content-defined Chunker, and records each chunk via store,
+// deduplicating identical content against the chunks table.
+func chunkAndStoreFile(store Store, scanner Scanner, path string, fileID int64) error {
+	f, err := scanner.Open(path)
+	if err != nil {
+		return fmt.Errorf("no se pudo abrir el archivo para chunking: %w", err)
+	}
+	defer f.Close()
+
+	chunker := NewChunker()
+	for chunk := range chunker.Split(f) {
+		if err := store.UpsertChunk(chunk); err != nil {
+			return err
+		}
+		if err := store.InsertFileChunk(fileID, chunk); err != nil {
+			return err
+		}
+	}
+	if err := chunker.Err(); err != nil {
+		return fmt.Errorf("el archivo se troceó de forma incompleta: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// fakeStore is a minimal in-memory Store, used only so scanAndStoreFiles can
+// be exercised against memScanner without a real database.
+type fakeStore struct {
+	typeMap  map[string]int
+	existing map[string]bool
+	inserted []FileRecord
+	nextID   int64
+}
+
+func (s *fakeStore) Migrate() error { return nil }
+
+func (s *fakeStore) LoadPrefixMap() (map[string]int, error) { return s.typeMap, nil }
+
+func (s *fakeStore) ExistingPaths(dir string) (map[string]bool, error) { return s.existing, nil }
+
+func (s *fakeStore) InsertBatch(records []FileRecord) (map[string]int64, error) {
+	ids := make(map[string]int64, len(records))
+	for _, rec := range records {
+		s.nextID++
+		s.inserted = append(s.inserted, rec)
+		ids[rec.Path] = s.nextID
+	}
+	return ids, nil
+}
+
+func (s *fakeStore) UpsertChunk(chunk Chunk) error { return nil }
+
+func (s *fakeStore) InsertFileChunk(fileID int64, chunk Chunk) error { return nil }
+
+func (s *fakeStore) Close() error { return nil }
+
+func testConfig() *Config {
+	return &Config{Backend: "mem", WatchDir: "/watch", MaxWorkers: 2, InsertBatchSize: 10}
+}
+
+func testLogger() Logger {
+	return newLogger(LoggingConfig{Level: "error"})
+}
+
+func TestScanAndStoreFilesWithMemScanner(t *testing.T) {
+	scanner := newMemScanner()
+	scanner.AddFile("/watch/invoice_1.txt", []byte("hola"), time.Now())
+	scanner.AddFile("/watch/unknown_2.txt", []byte("mundo"), time.Now())
+
+	store := &fakeStore{typeMap: map[string]int{"invoice_": 1}, existing: map[string]bool{}}
+
+	if err := scanAndStoreFiles(store, scanner, testConfig(), testLogger()); err != nil {
+		t.Fatalf("scanAndStoreFiles returned error: %v", err)
+	}
+
+	if len(store.inserted) != 1 {
+		t.Fatalf("expected 1 file inserted (matching prefix), got %d", len(store.inserted))
+	}
+	if store.inserted[0].Path != "/watch/invoice_1.txt" {
+		t.Errorf("expected invoice_1.txt inserted, got %s", store.inserted[0].Path)
+	}
+}
+
+func TestScanAndStoreFilesSkipsExistingPaths(t *testing.T) {
+	scanner := newMemScanner()
+	scanner.AddFile("/watch/invoice_1.txt", []byte("hola"), time.Now())
+
+	store := &fakeStore{
+		typeMap:  map[string]int{"invoice_": 1},
+		existing: map[string]bool{"/watch/invoice_1.txt": true},
+	}
+
+	if err := scanAndStoreFiles(store, scanner, testConfig(), testLogger()); err != nil {
+		t.Fatalf("scanAndStoreFiles returned error: %v", err)
+	}
+	if len(store.inserted) != 0 {
+		t.Fatalf("expected existing path to be skipped, got %d inserts", len(store.inserted))
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+)
+
+// Logger is a small structured logging interface modeled on
+// fclairamb/go-log: plain Printf-style logging makes it hard to filter or
+// ship scan events to a centralized pipeline, so callers attach contextual
+// fields (path, type_id, scan_id, worker_id) as key/value pairs instead of
+// formatting them into the message.
+type Logger interface {
+	Debug(msg string, kv ...any)
+	Info(msg string, kv ...any)
+	Warn(msg string, kv ...any)
+	Error(msg string, kv ...any)
+
+	// With returns a Logger that attaches kv to every call, so a scan or
+	// worker can scope its fields once instead of repeating them.
+	With(kv ...any) Logger
+}
+
+// LoggingConfig selects the verbosity and output format of the default
+// slog-backed Logger.
+type LoggingConfig struct {
+	Level  string `json:"level"`  // "debug", "info", "warn", or "error" (default "info")
+	Format string `json:"format"` // "json" or "text" (default "text")
+}
+
+// slogLogger is the default Logger implementation, backed by the standard
+// library's structured logger.
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// newLogger builds the default Logger from LoggingConfig.
+func newLogger(config LoggingConfig) Logger {
+	opts := &slog.HandlerOptions{Level: parseLogLevel(config.Level)}
+
+	var handler slog.Handler
+	if config.Format == "json" {
+		handler = slog.NewJSONHandler(os.Stdout, opts)
+	} else {
+		handler = slog.NewTextHandler(os.Stdout, opts)
+	}
+	return &slogLogger{logger: slog.New(handler)}
+}
+
+func parseLogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+func (l *slogLogger) Debug(msg string, kv ...any) { l.logger.Debug(msg, kv...) }
+func (l *slogLogger) Info(msg string, kv ...any)  { l.logger.Info(msg, kv...) }
+func (l *slogLogger) Warn(msg string, kv ...any)  { l.logger.Warn(msg, kv...) }
+func (l *slogLogger) Error(msg string, kv ...any) { l.logger.Error(msg, kv...) }
+
+func (l *slogLogger) With(kv ...any) Logger {
+	return &slogLogger{logger: l.logger.With(kv...)}
+}
@@ -1,21 +1,19 @@
 package main
 
 import (
-	"database/sql"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
 	"sort"
 	"strings"
+	"sync"
 	"time"
-
-	"github.com/pkg/sftp"
-	_ "github.com/sijms/go-ora/v2"
-	"golang.org/x/crypto/ssh"
 )
 
 type Config struct {
+	// OracleDSN is kept for configs written before Database.Driver existed;
+	// it's used as the DSN when Database.DSN is empty.
 	OracleDSN    string `json:"oracle_dsn"`
 	SSHUser      string `json:"ssh_user"`
 	SSHPassword  string `json:"ssh_password"`
@@ -23,12 +21,48 @@ type Config struct {
 	SSHPort      int    `json:"ssh_port"`
 	WatchDir     string `json:"watch_dir"`
 	ScanInterval int    `json:"scan_interval_hours"`
+
+	// Additional SSH auth methods, tried alongside SSHPassword: a private
+	// key (optionally passphrase-protected) and/or the running ssh-agent.
+	SSHPrivateKeyPath       string `json:"ssh_private_key_path"`
+	SSHPrivateKeyPassphrase string `json:"ssh_private_key_passphrase"`
+	SSHAgent                bool   `json:"ssh_agent"`
+
+	// Host key verification. KnownHostsPath takes precedence; SSHInsecure
+	// must be set explicitly to skip verification entirely.
+	KnownHostsPath string `json:"known_hosts_path"`
+	SSHInsecure    bool   `json:"ssh_insecure"`
+
+	// Backend selects which Scanner implementation walks WatchDir: "sftp"
+	// (default, current behavior), "local", or "mem" (tests only).
+	Backend      string             `json:"backend"`
+	LocalBackend LocalBackendConfig `json:"local_backend"`
+
+	Chunking ChunkingConfig `json:"chunking"`
+
+	// MaxWorkers bounds the worker pool that processes walked files
+	// concurrently; InsertBatchSize bounds how many rows go in each batch
+	// insert. Both default to sane values when unset.
+	MaxWorkers      int `json:"max_workers"`
+	InsertBatchSize int `json:"insert_batch_size"`
+
+	Database DatabaseConfig `json:"database"`
+	Log      LoggingConfig  `json:"log"`
 }
 
-var (
-	sshClient  *ssh.Client
-	sftpClient *sftp.Client
-)
+// ChunkingConfig gates the content-defined chunking/dedup subsystem, since
+// it multiplies the bandwidth cost of a scan by reading every new file in
+// full instead of just stat-ing it.
+type ChunkingConfig struct {
+	Enabled bool `json:"enabled"`
+}
+
+// LocalBackendConfig configures the "local" backend, for operators who want
+// to point the scanner at a local mount, NFS share, or S3-fuse mount instead
+// of a remote SFTP server.
+type LocalBackendConfig struct {
+	RootDir string `json:"root_dir"`
+}
 
 func main() {
 	config, err := loadConfig("config.json")
@@ -36,23 +70,52 @@ func main() {
 		log.Fatalf("Error cargando configuración: %v", err)
 	}
 
-	db, err := sql.Open("oracle", config.OracleDSN)
+	logger := newLogger(config.Log)
+
+	store, err := newStore(config)
 	if err != nil {
-		log.Fatalf("Error conectando a la base de datos Oracle: %v", err)
+		logger.Error("error conectando a la base de datos", "err", err)
+		os.Exit(1)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	if err := connectSSH(config.SSHUser, config.SSHPassword, config.SSHHost, config.SSHPort); err != nil {
-		log.Fatalf("Error conectando vía SSH: %v", err)
+	if err := store.Migrate(); err != nil {
+		logger.Error("error migrando el esquema", "err", err)
+		os.Exit(1)
+	}
+
+	isSFTP := config.Backend == "" || config.Backend == "sftp"
+	if isSFTP {
+		if err := connectSSH(config, logger); err != nil {
+			logger.Error("error conectando vía SSH", "err", err)
+			os.Exit(1)
+		}
+		defer closeSSH()
 	}
-	defer closeSSH()
 
 	interval := time.Duration(config.ScanInterval) * time.Hour
 
+	var scanID int
 	for {
-		err := scanAndStoreFiles(db, config.WatchDir)
+		scanID++
+		scanLogger := logger.With("scan_id", scanID)
+
+		if isSFTP {
+			if err := ensureSSHConnected(config, scanLogger); err != nil {
+				scanLogger.Error("error reconectando vía SSH", "err", err)
+				time.Sleep(interval)
+				continue
+			}
+		}
+
+		scanner, err := newScanner(config)
 		if err != nil {
-			log.Printf("Error durante escaneo: %v", err)
+			scanLogger.Error("error inicializando backend de escaneo", "err", err)
+			os.Exit(1)
+		}
+
+		if err := scanAndStoreFiles(store, scanner, config, scanLogger); err != nil {
+			scanLogger.Error("error durante escaneo", "err", err)
 		}
 		time.Sleep(interval)
 	}
@@ -80,107 +143,82 @@ func loadConfig(filename string) (*Config, error) {
 	if config.ScanInterval == 0 {
 		config.ScanInterval = 24
 	}
-
-	return &config, nil
-}
-
-func connectSSH(user, password, host string, port int) error {
-	config := &ssh.ClientConfig{
-		User: user,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         10 * time.Second,
-	}
-
-	addr := fmt.Sprintf("%s:%d", host, port)
-	client, err := ssh.Dial("tcp", addr, config)
-	if err != nil {
-		return fmt.Errorf("no se pudo conectar al servidor SSH: %w", err)
+	if config.MaxWorkers == 0 {
+		config.MaxWorkers = 4
 	}
-	sshClient = client
-
-	sftpC, err := sftp.NewClient(client)
-	if err != nil {
-		sshClient.Close()
-		return fmt.Errorf("no se pudo crear cliente SFTP: %w", err)
+	if config.InsertBatchSize == 0 {
+		config.InsertBatchSize = 500
 	}
-	sftpClient = sftpC
-	return nil
-}
 
-func closeSSH() {
-	if sftpClient != nil {
-		sftpClient.Close()
-	}
-	if sshClient != nil {
-		sshClient.Close()
-	}
+	return &config, nil
 }
 
-func scanAndStoreFiles(db *sql.DB, remoteDir string) error {
-	typeMap, err := loadTypePrefixMap(db)
+// scanAndStoreFiles walks config.WatchDir and stores any file not already in
+// the database. The walk itself stays single-threaded (one goroutine drives
+// scanner.Walk), but everything downstream of it — existence checks,
+// inserts, and optional chunking — fans out across a worker pool so a tree
+// with tens of thousands of entries doesn't serialize one DB round trip per
+// file. done is closed once every worker has returned, so the walking
+// goroutine can give up sending on walked instead of blocking forever if all
+// workers exit early (e.g. every InsertBatch call fails after a DB outage).
+func scanAndStoreFiles(store Store, scanner Scanner, config *Config, logger Logger) error {
+	typeMap, err := store.LoadPrefixMap()
 	if err != nil {
 		return fmt.Errorf("no se pudieron cargar los prefijos de tipo: %w", err)
 	}
 
-	walker := sftpClient.Walk(remoteDir)
-	for walker.Step() {
-		if err := walker.Err(); err != nil {
-			return err
-		}
-
-		fi := walker.Stat()
-		if fi.IsDir() {
-			continue
+	existingPaths, err := store.ExistingPaths(config.WatchDir)
+	if err != nil {
+		return fmt.Errorf("no se pudieron cargar las rutas existentes: %w", err)
+	}
+
+	walked := make(chan walkedFile)
+	errs := make(chan error, config.MaxWorkers+1)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(walked)
+		walker := scanner.Walk(config.WatchDir)
+		for walker.Step() {
+			if err := walker.Err(); err != nil {
+				errs <- err
+				return
+			}
+			fi := walker.Stat()
+			if fi.IsDir() {
+				continue
+			}
+			select {
+			case walked <- walkedFile{path: walker.Path(), info: fi}:
+			case <-done:
+				return
+			}
 		}
-
-		path := walker.Path()
-		exists, err := fileExistsInDB(db, path)
+	}()
+
+	var wg sync.WaitGroup
+	var modTimeWarnOnce sync.Once
+	for id := 0; id < config.MaxWorkers; id++ {
+		wg.Add(1)
+		go func(workerID int) {
+			defer wg.Done()
+			if err := runScanWorker(workerID, store, scanner, config, typeMap, existingPaths, walked, &modTimeWarnOnce, logger); err != nil {
+				errs <- err
+			}
+		}(id)
+	}
+	wg.Wait()
+	close(done)
+	close(errs)
+
+	for err := range errs {
 		if err != nil {
 			return err
 		}
-		if exists {
-			continue
-		}
-
-		typeID := matchPrefixToTypeID(fi.Name(), typeMap)
-		if typeID == 0 {
-			log.Printf("No se encontró prefijo coincidente para el archivo: %s (omitido)", fi.Name())
-			continue
-		}
-
-		modTime := fi.ModTime()
-		size := fi.Size()
-
-		insertFile(db, fi.Name(), path, size, modTime, typeID)
-		fmt.Printf("Insertado: %s [tipo_id: %d]\n", path, typeID)
 	}
 	return nil
 }
 
-func loadTypePrefixMap(db *sql.DB) (map[string]int, error) {
-	rows, err := db.Query(`SELECT prefix, id FROM file_types`)
-	if err != nil {
-		return nil, fmt.Errorf("error en consulta: %w", err)
-	}
-	defer rows.Close()
-
-	typeMap := make(map[string]int)
-	for rows.Next() {
-		var prefix string
-		var id int
-		if err := rows.Scan(&prefix, &id); err != nil {
-			log.Printf("Fila omitida: %v", err)
-			continue
-		}
-		typeMap[strings.ToLower(prefix)] = id
-	}
-
-	return typeMap, nil
-}
-
 func matchPrefixToTypeID(filename string, typeMap map[string]int) int {
 	filename = strings.ToLower(filename)
 
@@ -201,21 +239,3 @@ func matchPrefixToTypeID(filename string, typeMap map[string]int) int {
 	return 0
 }
 
-func fileExistsInDB(db *sql.DB, path string) (bool, error) {
-	var exists int
-	err := db.QueryRow(`SELECT COUNT(1) FROM files WHERE path = :1`, path).Scan(&exists)
-	if err != nil {
-		return false, err
-	}
-	return exists > 0, nil
-}
-
-func insertFile(db *sql.DB, name, path string, size int64, modTime time.Time, typeID int) {
-	_, err := db.Exec(
-		`INSERT INTO files (name, path, size, mod_time, type_id) VALUES (:1, :2, :3, :4, :5)`,
-		name, path, size, modTime, typeID,
-	)
-	if err != nil {
-		log.Printf("Error insertando archivo %s: %v", name, err)
-	}
-}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// walkedFile is one entry handed from the single walking goroutine to the
+// worker pool in scanAndStoreFiles.
+type walkedFile struct {
+	path string
+	info os.FileInfo
+}
+
+// modTimeEpochFallbackYear is the cutoff below which a reported ModTime is
+// treated as bogus rather than real: some SFTP servers report the current
+// time or 1601 (Windows FILETIME epoch) when they don't actually track
+// mtimes, and propagating that into the store as if it were real would
+// mislead anything downstream that reasons about file age. Only the 1601
+// case (and a zero time) falls below the cutoff; a server that reports the
+// current time is not detected and its value is kept.
+const modTimeEpochFallbackYear = 1971
+
+// normalizeModTime follows the sftpgo approach of substituting the Unix
+// epoch for an obviously-bogus ModTime, and always stores times in UTC so
+// comparisons across servers in different timezones stay meaningful. warnOnce
+// logs the fallback at most once per scan, since a tree with one broken
+// server can otherwise flood the log with an identical warning per file.
+func normalizeModTime(modTime time.Time, warnOnce *sync.Once, logger Logger) (time.Time, string) {
+	if modTime.IsZero() || modTime.Year() < modTimeEpochFallbackYear {
+		warnOnce.Do(func() {
+			logger.Warn("mod_time inválido detectado, usando epoch_fallback para esta pasada de escaneo")
+		})
+		return time.Unix(0, 0).UTC(), "epoch_fallback"
+	}
+	return modTime.UTC(), "sftp"
+}
+
+// newWorkerScanner gives each worker its own connection where the backend
+// needs one. For SFTP this means a fresh sftp.Client multiplexed over the
+// same ssh.Client, since a single sftp.Client is not meant to be shared
+// across concurrent callers issuing independent requests; local/mem
+// backends have no such per-connection state and are shared as-is.
+func newWorkerScanner(config *Config, base Scanner) (Scanner, error) {
+	switch config.Backend {
+	case "", "sftp":
+		client, err := sftp.NewClient(sshClient)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo abrir sesión SFTP para el worker: %w", err)
+		}
+		return &sftpScanner{client: client}, nil
+	default:
+		return base, nil
+	}
+}
+
+// runScanWorker pulls walked files off the shared channel, batches them into
+// store.InsertBatch calls of up to config.InsertBatchSize rows, and (when
+// enabled) chunks each newly inserted file. Each worker owns its own SFTP
+// session; InsertBatch commits its own transaction per call.
+func runScanWorker(workerID int, store Store, scanner Scanner, config *Config, typeMap map[string]int, existingPaths map[string]bool, walked <-chan walkedFile, modTimeWarnOnce *sync.Once, logger Logger) error {
+	workerLogger := logger.With("worker_id", workerID)
+
+	workerScanner, err := newWorkerScanner(config, scanner)
+	if err != nil {
+		return fmt.Errorf("worker %d: %w", workerID, err)
+	}
+	if closer, ok := workerScanner.(interface{ Close() error }); ok {
+		defer closer.Close()
+	}
+
+	var batch []FileRecord
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		ids, err := store.InsertBatch(batch)
+		if err != nil {
+			return fmt.Errorf("worker %d: %w", workerID, err)
+		}
+		for _, rec := range batch {
+			workerLogger.Info("archivo insertado", "path", rec.Path, "type_id", rec.TypeID)
+			if !config.Chunking.Enabled {
+				continue
+			}
+			id, ok := ids[rec.Path]
+			if !ok {
+				continue
+			}
+			if err := chunkAndStoreFile(store, workerScanner, rec.Path, id); err != nil {
+				workerLogger.Error("error troceando archivo", "path", rec.Path, "err", err)
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	for wf := range walked {
+		if existingPaths[wf.path] {
+			continue
+		}
+
+		typeID := matchPrefixToTypeID(wf.info.Name(), typeMap)
+		if typeID == 0 {
+			workerLogger.Warn("prefijo no encontrado, archivo omitido", "path", wf.path)
+			continue
+		}
+
+		modTime, modTimeSource := normalizeModTime(wf.info.ModTime(), modTimeWarnOnce, workerLogger)
+		batch = append(batch, FileRecord{
+			Name:          wf.info.Name(),
+			Path:          wf.path,
+			Size:          wf.info.Size(),
+			ModTime:       modTime,
+			ModTimeSource: modTimeSource,
+			TypeID:        typeID,
+		})
+
+		if len(batch) >= config.InsertBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
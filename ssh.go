@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+)
+
+var (
+	sshClient  *ssh.Client
+	sftpClient *sftp.Client
+)
+
+// connectSSH dials the configured SSH host and opens an SFTP session on top
+// of it, trying every auth method the config enables (private key, agent,
+// password) in turn. The resulting clients are stashed in the
+// package-level sshClient/sftpClient globals that the sftp Scanner backend
+// reads from.
+func connectSSH(config *Config, logger Logger) error {
+	auths, err := sshAuthMethods(config)
+	if err != nil {
+		return fmt.Errorf("no se pudo preparar la autenticación SSH: %w", err)
+	}
+
+	hostKeyCallback, err := sshHostKeyCallback(config)
+	if err != nil {
+		return fmt.Errorf("no se pudo preparar la verificación de host SSH: %w", err)
+	}
+
+	clientConfig := &ssh.ClientConfig{
+		User:            config.SSHUser,
+		Auth:            auths,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         10 * time.Second,
+	}
+
+	addr := fmt.Sprintf("%s:%d", config.SSHHost, config.SSHPort)
+	client, err := ssh.Dial("tcp", addr, clientConfig)
+	if err != nil {
+		return fmt.Errorf("no se pudo conectar al servidor SSH: %w", err)
+	}
+	sshClient = client
+
+	sftpC, err := sftp.NewClient(client)
+	if err != nil {
+		sshClient.Close()
+		return fmt.Errorf("no se pudo crear cliente SFTP: %w", err)
+	}
+	sftpClient = sftpC
+	logger.Info("conectado al servidor SSH", "host", config.SSHHost, "port", config.SSHPort)
+	return nil
+}
+
+// sshAuthMethods builds the list of ssh.AuthMethod to try, in the order:
+// private key, SSH agent, password. Empty/disabled options are skipped.
+func sshAuthMethods(config *Config) ([]ssh.AuthMethod, error) {
+	var auths []ssh.AuthMethod
+
+	if config.SSHPrivateKeyPath != "" {
+		keyAuth, err := sshPrivateKeyAuth(config.SSHPrivateKeyPath, config.SSHPrivateKeyPassphrase)
+		if err != nil {
+			return nil, err
+		}
+		auths = append(auths, keyAuth)
+	}
+
+	if config.SSHAgent {
+		agentAuth, err := sshAgentAuth()
+		if err != nil {
+			return nil, err
+		}
+		auths = append(auths, agentAuth)
+	}
+
+	if config.SSHPassword != "" {
+		auths = append(auths, ssh.Password(config.SSHPassword))
+	}
+
+	if len(auths) == 0 {
+		return nil, fmt.Errorf("no hay método de autenticación SSH configurado (ssh_password, ssh_private_key_path o ssh_agent)")
+	}
+
+	return auths, nil
+}
+
+func sshPrivateKeyAuth(path, passphrase string) (ssh.AuthMethod, error) {
+	keyData, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo leer la clave privada: %w", err)
+	}
+
+	var signer ssh.Signer
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(keyData, []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey(keyData)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo parsear la clave privada: %w", err)
+	}
+
+	return ssh.PublicKeys(signer), nil
+}
+
+func sshAgentAuth() (ssh.AuthMethod, error) {
+	sock := os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, fmt.Errorf("ssh_agent está activado pero SSH_AUTH_SOCK no está definido")
+	}
+
+	conn, err := net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo conectar al ssh-agent: %w", err)
+	}
+
+	agentClient := agent.NewClient(conn)
+	return ssh.PublicKeysCallback(agentClient.Signers), nil
+}
+
+// sshHostKeyCallback verifies the remote host key against known_hosts_path
+// when configured. Falling back to an unverified connection requires the
+// operator to explicitly opt in via ssh_insecure, since this daemon runs
+// unattended against a configured host.
+func sshHostKeyCallback(config *Config) (ssh.HostKeyCallback, error) {
+	if config.KnownHostsPath != "" {
+		callback, err := knownhosts.New(config.KnownHostsPath)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo cargar known_hosts_path: %w", err)
+		}
+		return callback, nil
+	}
+
+	if config.SSHInsecure {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	return nil, fmt.Errorf("known_hosts_path no está configurado; defina ssh_insecure: true para omitir la verificación de host (no recomendado)")
+}
+
+func closeSSH() {
+	if sftpClient != nil {
+		sftpClient.Close()
+	}
+	if sshClient != nil {
+		sshClient.Close()
+	}
+}
+
+// sshAlive reports whether the current SSH connection still looks usable.
+// It sends a no-op keepalive request, which fails immediately once the
+// underlying connection has dropped (idle disconnect, network blip, etc).
+func sshAlive() bool {
+	if sshClient == nil {
+		return false
+	}
+	_, _, err := sshClient.SendRequest("keepalive@scanner", true, nil)
+	return err == nil
+}
+
+// ensureSSHConnected transparently re-dials the SSH/SFTP connection if it
+// has died since the last scan, so scanAndStoreFiles never has to handle a
+// closed client.
+func ensureSSHConnected(config *Config, logger Logger) error {
+	if sshAlive() && sftpClient != nil {
+		return nil
+	}
+	logger.Warn("conexión SSH caída, reconectando")
+	closeSSH()
+	return connectSSH(config, logger)
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// FileRecord is a file queued for insertion, shared by every Store
+// implementation.
+type FileRecord struct {
+	Name          string
+	Path          string
+	Size          int64
+	ModTime       time.Time
+	ModTimeSource string
+	TypeID        int
+}
+
+// Store abstracts persistence so the scanner isn't wired to Oracle's
+// placeholder syntax. Each implementation owns its own DDL and SQL dialect;
+// main only ever talks to this interface.
+type Store interface {
+	// Migrate creates the files/file_types/chunks/file_chunks schema if it
+	// doesn't already exist.
+	Migrate() error
+
+	// LoadPrefixMap returns the filename-prefix -> type_id lookup used to
+	// classify scanned files.
+	LoadPrefixMap() (map[string]int, error)
+
+	// ExistingPaths returns every path already recorded under dir, so the
+	// scanner can skip them without a query per file.
+	ExistingPaths(dir string) (map[string]bool, error)
+
+	// InsertBatch inserts the records and returns the id Store assigned to
+	// each newly inserted path, keyed by path. The Postgres and SQLite
+	// backends skip a path that is already recorded and leave it out of the
+	// map; the Oracle backend fails the whole batch on such a path.
+	InsertBatch(records []FileRecord) (map[string]int64, error)
+
+	// UpsertChunk registers a chunk's content hash once, deduplicating
+	// identical content across files.
+	UpsertChunk(chunk Chunk) error
+
+	// InsertFileChunk records that fileID contains chunk at chunk.Offset.
+	InsertFileChunk(fileID int64, chunk Chunk) error
+
+	Close() error
+}
+
+// DatabaseConfig selects and configures the Store backend.
+type DatabaseConfig struct {
+	// Driver is "oracle" (default, current behavior), "postgres", or
+	// "sqlite".
+	Driver string `json:"driver"`
+	DSN    string `json:"dsn"`
+}
+
+// newStore builds the Store selected by config.Database.Driver, falling
+// back to "oracle" for configs written before this field existed (which
+// used OracleDSN directly).
+func newStore(config *Config) (Store, error) {
+	dsn := config.Database.DSN
+	if dsn == "" {
+		dsn = config.OracleDSN
+	}
+
+	switch config.Database.Driver {
+	case "", "oracle":
+		return newOracleStore(dsn)
+	case "postgres":
+		return newPostgresStore(dsn)
+	case "sqlite":
+		return newSQLiteStore(dsn)
+	default:
+		return nil, fmt.Errorf("driver de base de datos desconocido: %q", config.Database.Driver)
+	}
+}
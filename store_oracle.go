@@ -0,0 +1,187 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/sijms/go-ora/v2"
+)
+
+// oracleStore is the original backend: Oracle via go-ora, with its ":N"
+// bind-variable placeholder syntax and INSERT ALL/MERGE dialect.
+type oracleStore struct {
+	db *sql.DB
+}
+
+func newOracleStore(dsn string) (Store, error) {
+	db, err := sql.Open("oracle", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a la base de datos Oracle: %w", err)
+	}
+	return &oracleStore{db: db}, nil
+}
+
+func (s *oracleStore) Migrate() error {
+	stmts := []string{
+		`BEGIN
+			EXECUTE IMMEDIATE 'CREATE TABLE file_types (id NUMBER PRIMARY KEY, prefix VARCHAR2(255))';
+		EXCEPTION WHEN OTHERS THEN IF SQLCODE != -955 THEN RAISE; END IF;
+		END;`,
+		`BEGIN
+			EXECUTE IMMEDIATE 'CREATE TABLE files (
+				id NUMBER GENERATED ALWAYS AS IDENTITY PRIMARY KEY,
+				name VARCHAR2(255),
+				path VARCHAR2(4000) UNIQUE,
+				size NUMBER,
+				mod_time TIMESTAMP,
+				mod_time_source VARCHAR2(32),
+				type_id NUMBER
+			)';
+		EXCEPTION WHEN OTHERS THEN IF SQLCODE != -955 THEN RAISE; END IF;
+		END;`,
+		`BEGIN
+			EXECUTE IMMEDIATE 'CREATE TABLE chunks (sha256 VARCHAR2(64) PRIMARY KEY, length NUMBER)';
+		EXCEPTION WHEN OTHERS THEN IF SQLCODE != -955 THEN RAISE; END IF;
+		END;`,
+		`BEGIN
+			EXECUTE IMMEDIATE 'CREATE TABLE file_chunks (
+				file_id NUMBER REFERENCES files(id),
+				offset_bytes NUMBER,
+				length NUMBER,
+				sha256 VARCHAR2(64) REFERENCES chunks(sha256)
+			)';
+		EXCEPTION WHEN OTHERS THEN IF SQLCODE != -955 THEN RAISE; END IF;
+		END;`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("error en migración Oracle: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *oracleStore) LoadPrefixMap() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT prefix, id FROM file_types`)
+	if err != nil {
+		return nil, fmt.Errorf("error en consulta: %w", err)
+	}
+	defer rows.Close()
+
+	typeMap := make(map[string]int)
+	for rows.Next() {
+		var prefix string
+		var id int
+		if err := rows.Scan(&prefix, &id); err != nil {
+			return nil, err
+		}
+		typeMap[strings.ToLower(prefix)] = id
+	}
+	return typeMap, nil
+}
+
+func (s *oracleStore) ExistingPaths(dir string) (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT path FROM files WHERE path LIKE :1 || '%'`, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error en consulta: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		existing[path] = true
+	}
+	return existing, nil
+}
+
+func (s *oracleStore) InsertBatch(records []FileRecord) (map[string]int64, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	var sb strings.Builder
+	sb.WriteString("INSERT ALL\n")
+	args := make([]any, 0, len(records)*6)
+	for i, rec := range records {
+		base := i * 6
+		fmt.Fprintf(&sb,
+			"  INTO files (name, path, size, mod_time, type_id, mod_time_source) VALUES (:%d, :%d, :%d, :%d, :%d, :%d)\n",
+			base+1, base+2, base+3, base+4, base+5, base+6,
+		)
+		args = append(args, rec.Name, rec.Path, rec.Size, rec.ModTime, rec.TypeID, rec.ModTimeSource)
+	}
+	sb.WriteString("SELECT * FROM dual")
+
+	if _, err := tx.Exec(sb.String(), args...); err != nil {
+		return nil, fmt.Errorf("no se pudo insertar el lote de archivos: %w", err)
+	}
+
+	placeholders := make([]string, len(records))
+	idArgs := make([]any, len(records))
+	for i, rec := range records {
+		placeholders[i] = fmt.Sprintf(":%d", i+1)
+		idArgs[i] = rec.Path
+	}
+	query := fmt.Sprintf(`SELECT id, path FROM files WHERE path IN (%s)`, strings.Join(placeholders, ", "))
+	rows, err := tx.Query(query, idArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudieron recuperar los ids insertados: %w", err)
+	}
+	defer rows.Close()
+
+	ids := make(map[string]int64, len(records))
+	for rows.Next() {
+		var id int64
+		var path string
+		if err := rows.Scan(&id, &path); err != nil {
+			return nil, err
+		}
+		ids[path] = id
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("no se pudo confirmar transacción: %w", err)
+	}
+	return ids, nil
+}
+
+func (s *oracleStore) UpsertChunk(chunk Chunk) error {
+	_, err := s.db.Exec(`
+		MERGE INTO chunks c
+		USING (SELECT :1 AS sha256, :2 AS length FROM dual) src
+		ON (c.sha256 = src.sha256)
+		WHEN NOT MATCHED THEN INSERT (sha256, length) VALUES (src.sha256, src.length)`,
+		chunk.SHA256, chunk.Length,
+	)
+	if err != nil {
+		return fmt.Errorf("no se pudo registrar el chunk %s: %w", chunk.SHA256, err)
+	}
+	return nil
+}
+
+func (s *oracleStore) InsertFileChunk(fileID int64, chunk Chunk) error {
+	_, err := s.db.Exec(
+		`INSERT INTO file_chunks (file_id, offset_bytes, length, sha256) VALUES (:1, :2, :3, :4)`,
+		fileID, chunk.Offset, chunk.Length, chunk.SHA256,
+	)
+	if err != nil {
+		return fmt.Errorf("no se pudo insertar file_chunks para archivo %d: %w", fileID, err)
+	}
+	return nil
+}
+
+func (s *oracleStore) Close() error {
+	return s.db.Close()
+}
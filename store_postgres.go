@@ -0,0 +1,161 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/lib/pq"
+)
+
+// postgresStore backs Store with Postgres via lib/pq: "$N" placeholders and
+// an ON CONFLICT/RETURNING dialect instead of Oracle's MERGE/INSERT ALL.
+type postgresStore struct {
+	db *sql.DB
+}
+
+func newPostgresStore(dsn string) (Store, error) {
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a la base de datos Postgres: %w", err)
+	}
+	return &postgresStore{db: db}, nil
+}
+
+func (s *postgresStore) Migrate() error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS file_types (id SERIAL PRIMARY KEY, prefix TEXT)`,
+		`CREATE TABLE IF NOT EXISTS files (
+			id BIGSERIAL PRIMARY KEY,
+			name TEXT,
+			path TEXT UNIQUE,
+			size BIGINT,
+			mod_time TIMESTAMPTZ,
+			mod_time_source TEXT,
+			type_id INTEGER
+		)`,
+		`CREATE TABLE IF NOT EXISTS chunks (sha256 TEXT PRIMARY KEY, length BIGINT)`,
+		`CREATE TABLE IF NOT EXISTS file_chunks (
+			file_id BIGINT REFERENCES files(id),
+			offset_bytes BIGINT,
+			length BIGINT,
+			sha256 TEXT REFERENCES chunks(sha256)
+		)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("error en migración Postgres: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *postgresStore) LoadPrefixMap() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT prefix, id FROM file_types`)
+	if err != nil {
+		return nil, fmt.Errorf("error en consulta: %w", err)
+	}
+	defer rows.Close()
+
+	typeMap := make(map[string]int)
+	for rows.Next() {
+		var prefix string
+		var id int
+		if err := rows.Scan(&prefix, &id); err != nil {
+			return nil, err
+		}
+		typeMap[strings.ToLower(prefix)] = id
+	}
+	return typeMap, nil
+}
+
+func (s *postgresStore) ExistingPaths(dir string) (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT path FROM files WHERE path LIKE $1 || '%'`, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error en consulta: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		existing[path] = true
+	}
+	return existing, nil
+}
+
+func (s *postgresStore) InsertBatch(records []FileRecord) (map[string]int64, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	var sb strings.Builder
+	sb.WriteString(`INSERT INTO files (name, path, size, mod_time, type_id, mod_time_source) VALUES `)
+	args := make([]any, 0, len(records)*6)
+	for i, rec := range records {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		base := i * 6
+		fmt.Fprintf(&sb, "($%d, $%d, $%d, $%d, $%d, $%d)", base+1, base+2, base+3, base+4, base+5, base+6)
+		args = append(args, rec.Name, rec.Path, rec.Size, rec.ModTime, rec.TypeID, rec.ModTimeSource)
+	}
+	sb.WriteString(` ON CONFLICT (path) DO NOTHING RETURNING id, path`)
+
+	rows, err := tx.Query(sb.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo insertar el lote de archivos: %w", err)
+	}
+
+	ids := make(map[string]int64, len(records))
+	for rows.Next() {
+		var id int64
+		var path string
+		if err := rows.Scan(&id, &path); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids[path] = id
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("no se pudo confirmar transacción: %w", err)
+	}
+	return ids, nil
+}
+
+func (s *postgresStore) UpsertChunk(chunk Chunk) error {
+	_, err := s.db.Exec(
+		`INSERT INTO chunks (sha256, length) VALUES ($1, $2) ON CONFLICT (sha256) DO NOTHING`,
+		chunk.SHA256, chunk.Length,
+	)
+	if err != nil {
+		return fmt.Errorf("no se pudo registrar el chunk %s: %w", chunk.SHA256, err)
+	}
+	return nil
+}
+
+func (s *postgresStore) InsertFileChunk(fileID int64, chunk Chunk) error {
+	_, err := s.db.Exec(
+		`INSERT INTO file_chunks (file_id, offset_bytes, length, sha256) VALUES ($1, $2, $3, $4)`,
+		fileID, chunk.Offset, chunk.Length, chunk.SHA256,
+	)
+	if err != nil {
+		return fmt.Errorf("no se pudo insertar file_chunks para archivo %d: %w", fileID, err)
+	}
+	return nil
+}
+
+func (s *postgresStore) Close() error {
+	return s.db.Close()
+}
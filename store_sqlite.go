@@ -0,0 +1,168 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// sqliteStore backs Store with SQLite via mattn/go-sqlite3, useful for tests
+// and small deployments that don't want an Oracle license or a Postgres
+// server running.
+type sqliteStore struct {
+	db *sql.DB
+}
+
+func newSQLiteStore(dsn string) (Store, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("error conectando a la base de datos SQLite: %w", err)
+	}
+	return &sqliteStore{db: db}, nil
+}
+
+func (s *sqliteStore) Migrate() error {
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS file_types (id INTEGER PRIMARY KEY, prefix TEXT)`,
+		`CREATE TABLE IF NOT EXISTS files (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			path TEXT UNIQUE,
+			size INTEGER,
+			mod_time DATETIME,
+			mod_time_source TEXT,
+			type_id INTEGER
+		)`,
+		`CREATE TABLE IF NOT EXISTS chunks (sha256 TEXT PRIMARY KEY, length INTEGER)`,
+		`CREATE TABLE IF NOT EXISTS file_chunks (
+			file_id INTEGER REFERENCES files(id),
+			offset_bytes INTEGER,
+			length INTEGER,
+			sha256 TEXT REFERENCES chunks(sha256)
+		)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := s.db.Exec(stmt); err != nil {
+			return fmt.Errorf("error en migración SQLite: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *sqliteStore) LoadPrefixMap() (map[string]int, error) {
+	rows, err := s.db.Query(`SELECT prefix, id FROM file_types`)
+	if err != nil {
+		return nil, fmt.Errorf("error en consulta: %w", err)
+	}
+	defer rows.Close()
+
+	typeMap := make(map[string]int)
+	for rows.Next() {
+		var prefix string
+		var id int
+		if err := rows.Scan(&prefix, &id); err != nil {
+			return nil, err
+		}
+		typeMap[strings.ToLower(prefix)] = id
+	}
+	return typeMap, nil
+}
+
+func (s *sqliteStore) ExistingPaths(dir string) (map[string]bool, error) {
+	rows, err := s.db.Query(`SELECT path FROM files WHERE path LIKE ? || '%'`, dir)
+	if err != nil {
+		return nil, fmt.Errorf("error en consulta: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			return nil, err
+		}
+		existing[path] = true
+	}
+	return existing, nil
+}
+
+// InsertBatch inserts one row per statement inside a single transaction.
+// Unlike Oracle's INSERT ALL or Postgres' multi-row VALUES ... RETURNING,
+// which each insert the whole batch in one statement, SQLite only exposes
+// LastInsertId() per statement, so each row is inserted and its id read on
+// its own.
+func (s *sqliteStore) InsertBatch(records []FileRecord) (map[string]int64, error) {
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo iniciar transacción: %w", err)
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR IGNORE INTO files (name, path, size, mod_time, type_id, mod_time_source) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return nil, fmt.Errorf("no se pudo preparar la inserción: %w", err)
+	}
+	defer stmt.Close()
+
+	ids := make(map[string]int64, len(records))
+	for _, rec := range records {
+		result, err := stmt.Exec(rec.Name, rec.Path, rec.Size, rec.ModTime, rec.TypeID, rec.ModTimeSource)
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo insertar archivo %s: %w", rec.Path, err)
+		}
+
+		// INSERT OR IGNORE silently skips conflicting rows without erroring,
+		// and LastInsertId() then reports the rowid of the last row this
+		// statement actually inserted - not 0, and not this row's id. Check
+		// RowsAffected so a skipped path doesn't get mapped to some other
+		// file's id, matching Postgres's RETURNING, which simply omits
+		// conflicted rows.
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo determinar si se insertó el archivo %s: %w", rec.Path, err)
+		}
+		if affected == 0 {
+			continue
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo obtener el id insertado para %s: %w", rec.Path, err)
+		}
+		ids[rec.Path] = id
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("no se pudo confirmar transacción: %w", err)
+	}
+	return ids, nil
+}
+
+func (s *sqliteStore) UpsertChunk(chunk Chunk) error {
+	_, err := s.db.Exec(`INSERT OR IGNORE INTO chunks (sha256, length) VALUES (?, ?)`, chunk.SHA256, chunk.Length)
+	if err != nil {
+		return fmt.Errorf("no se pudo registrar el chunk %s: %w", chunk.SHA256, err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) InsertFileChunk(fileID int64, chunk Chunk) error {
+	_, err := s.db.Exec(
+		`INSERT INTO file_chunks (file_id, offset_bytes, length, sha256) VALUES (?, ?, ?, ?)`,
+		fileID, chunk.Offset, chunk.Length, chunk.SHA256,
+	)
+	if err != nil {
+		return fmt.Errorf("no se pudo insertar file_chunks para archivo %d: %w", fileID, err)
+	}
+	return nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
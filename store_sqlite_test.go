@@ -0,0 +1,85 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestSQLiteStore(t *testing.T) *sqliteStore {
+	t.Helper()
+	store, err := newSQLiteStore(":memory:")
+	if err != nil {
+		t.Fatalf("newSQLiteStore: %v", err)
+	}
+	s := store.(*sqliteStore)
+	if err := s.Migrate(); err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestSQLiteStoreInsertBatchAndExistingPaths(t *testing.T) {
+	s := newTestSQLiteStore(t)
+
+	records := []FileRecord{
+		{Name: "invoice_1.txt", Path: "/watch/invoice_1.txt", Size: 4, ModTime: time.Now().UTC(), ModTimeSource: "sftp", TypeID: 1},
+		{Name: "invoice_2.txt", Path: "/watch/invoice_2.txt", Size: 8, ModTime: time.Now().UTC(), ModTimeSource: "sftp", TypeID: 1},
+	}
+
+	ids, err := s.InsertBatch(records)
+	if err != nil {
+		t.Fatalf("InsertBatch: %v", err)
+	}
+	if len(ids) != 2 {
+		t.Fatalf("expected 2 ids, got %d: %v", len(ids), ids)
+	}
+	if ids["/watch/invoice_1.txt"] == ids["/watch/invoice_2.txt"] {
+		t.Fatalf("expected distinct ids, got the same id for both paths: %v", ids)
+	}
+
+	existing, err := s.ExistingPaths("/watch")
+	if err != nil {
+		t.Fatalf("ExistingPaths: %v", err)
+	}
+	if !existing["/watch/invoice_1.txt"] || !existing["/watch/invoice_2.txt"] {
+		t.Fatalf("expected both paths to be reported as existing, got %v", existing)
+	}
+}
+
+// TestSQLiteStoreInsertBatchSkipsConflictingPath is a regression test for the
+// LastInsertId-after-INSERT-OR-IGNORE bug fixed in 25e88ec: re-inserting an
+// already-known path must be left out of the returned id map rather than
+// mapped to whatever row the statement last actually inserted.
+func TestSQLiteStoreInsertBatchSkipsConflictingPath(t *testing.T) {
+	s := newTestSQLiteStore(t)
+
+	first := []FileRecord{
+		{Name: "invoice_1.txt", Path: "/watch/invoice_1.txt", Size: 4, ModTime: time.Now().UTC(), ModTimeSource: "sftp", TypeID: 1},
+	}
+	firstIDs, err := s.InsertBatch(first)
+	if err != nil {
+		t.Fatalf("InsertBatch (first): %v", err)
+	}
+	originalID := firstIDs["/watch/invoice_1.txt"]
+
+	second := []FileRecord{
+		{Name: "invoice_1.txt", Path: "/watch/invoice_1.txt", Size: 4, ModTime: time.Now().UTC(), ModTimeSource: "sftp", TypeID: 1},
+		{Name: "invoice_2.txt", Path: "/watch/invoice_2.txt", Size: 8, ModTime: time.Now().UTC(), ModTimeSource: "sftp", TypeID: 1},
+	}
+	secondIDs, err := s.InsertBatch(second)
+	if err != nil {
+		t.Fatalf("InsertBatch (second): %v", err)
+	}
+
+	if id, ok := secondIDs["/watch/invoice_1.txt"]; ok {
+		t.Fatalf("expected already-existing path to be omitted from the id map, got id %d", id)
+	}
+	newID, ok := secondIDs["/watch/invoice_2.txt"]
+	if !ok {
+		t.Fatalf("expected the new path to be present in the id map, got %v", secondIDs)
+	}
+	if newID == originalID {
+		t.Fatalf("new path was mapped to the original row's id %d", originalID)
+	}
+}